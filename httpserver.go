@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	chart "github.com/wcharczuk/go-chart/v2"
+)
+
+// timeLayout is the RFC3339 format used for timestamps in /results.json.
+const timeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// serveHTTP starts the embedded metrics/chart server on addr and blocks
+// until it exits (which should only happen on a listener error), logging
+// that error. It's started in its own goroutine alongside the Fyne window
+// so the tool can run headless and still be scraped or embedded elsewhere.
+func serveHTTP(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chart.png", chartPNGHandler)
+	mux.HandleFunc("/chart.svg", chartSVGHandler)
+	mux.HandleFunc("/metrics", metricsHandler)
+	mux.HandleFunc("/results.json", resultsJSONHandler)
+
+	log.Printf("serving chart/metrics endpoints on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Println("http server error:", err)
+	}
+}
+
+func chartPNGHandler(w http.ResponseWriter, r *http.Request) {
+	graph, release, ok := buildLatencyChart()
+	if !ok {
+		http.Error(w, "not enough data yet", http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
+	w.Header().Set("Content-Type", "image/png")
+	if err := graph.Render(chart.PNG, w); err != nil {
+		log.Println("Error rendering chart.png:", err)
+	}
+}
+
+func chartSVGHandler(w http.ResponseWriter, r *http.Request) {
+	graph, release, ok := buildLatencyChart()
+	if !ok {
+		http.Error(w, "not enough data yet", http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if err := graph.Render(chart.SVG, w); err != nil {
+		log.Println("Error rendering chart.svg:", err)
+	}
+}
+
+// metricsHandler exposes Prometheus text-format gauges/counters for the
+// latest latency and cumulative error count of every known target.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP blip_latency_ms Latest measured latency in milliseconds for a target.")
+	fmt.Fprintln(w, "# TYPE blip_latency_ms gauge")
+	for _, id := range results.ids() {
+		sample, ok := results.latest(id)
+		if !ok || sample.LatencyMs < 0 {
+			continue
+		}
+		fmt.Fprintf(w, "blip_latency_ms{target=%q} %d\n", id, sample.LatencyMs)
+	}
+
+	fmt.Fprintln(w, "# HELP blip_errors_total Total number of failed probes for a target.")
+	fmt.Fprintln(w, "# TYPE blip_errors_total counter")
+	for _, id := range results.ids() {
+		fmt.Fprintf(w, "blip_errors_total{target=%q} %d\n", id, results.errorTotal(id))
+	}
+}
+
+// resultsJSONSample is the JSON shape of one Sample in the /results.json
+// response.
+type resultsJSONSample struct {
+	Timestamp string `json:"timestamp"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// resultsJSONHandler dumps every known target's ring buffer as JSON, oldest
+// sample first.
+func resultsJSONHandler(w http.ResponseWriter, r *http.Request) {
+	out := make(map[string][]resultsJSONSample)
+	rs := renderSlicePool.Get().(*renderSlices)
+	defer renderSlicePool.Put(rs)
+
+	for _, id := range results.ids() {
+		n := results.snapshot(id, rs.samples)
+		samples := make([]resultsJSONSample, n)
+		for i, s := range rs.samples[:n] {
+			samples[i] = resultsJSONSample{Timestamp: s.Timestamp.Format(timeLayout), LatencyMs: s.LatencyMs}
+		}
+		out[id] = samples
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		log.Println("Error encoding results.json:", err)
+	}
+}
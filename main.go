@@ -2,12 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"log"
-	"net/http"
 	"sync"
 	"time"
 
@@ -17,128 +18,395 @@ import (
 	"fyne.io/fyne/v2/container"
 
 	chart "github.com/wcharczuk/go-chart/v2"
-)
+	"github.com/wcharczuk/go-chart/v2/drawing"
 
-// PingResult holds the timestamp and measured latencies.
-type PingResult struct {
-	Timestamp       time.Time
-	GstaticLatency  int64 // in milliseconds
-	ApenwarrLatency int64 // in milliseconds
-}
+	"github.com/Ivan-Feofanov/go-blip/probe"
+	"github.com/Ivan-Feofanov/go-blip/storage"
+	"github.com/Ivan-Feofanov/go-blip/throughput"
+)
 
 var (
-	results   []PingResult
-	resultsMu sync.Mutex
+	results       = newTargetStore()
+	buckets       = newBucketStore()
+	throughputBuf = &throughput.Buffer{}
 )
 
-const (
-	gstaticURL  = "https://www.gstatic.com/generate_204"
-	apenwarrURL = "https://apenwarr.ca"
-)
+// seriesColors is cycled through when assigning a color to each target's
+// line on the chart, so the same target keeps the same color across ticks.
+var seriesColors = []drawing.Color{
+	chart.ColorBlue,
+	chart.ColorGreen,
+	chart.ColorOrange,
+	chart.ColorRed,
+	chart.ColorBlack,
+}
 
-// doPings pings two endpoints every second.
-func doPings() {
-	for {
-		now := time.Now()
+// renderSlices bundles the scratch slices renderChart needs for one
+// target's series, so they can be acquired from and returned to
+// renderSlicePool as a single unit instead of reallocating every tick.
+type renderSlices struct {
+	samples []Sample
+	xValues []time.Time
+	yValues []float64
+}
 
-		// Ping gstatic.
-		start := time.Now()
-		resp, err := http.Get(gstaticURL)
-		gLatency := time.Since(start).Milliseconds()
-		if err != nil {
-			log.Println("gstatic ping error:", err)
-			gLatency = -1
-		} else {
-			resp.Body.Close()
+var renderSlicePool = sync.Pool{
+	New: func() interface{} {
+		return &renderSlices{
+			samples: make([]Sample, ringCapacity),
+			xValues: make([]time.Time, ringCapacity),
+			yValues: make([]float64, ringCapacity),
 		}
+	},
+}
 
-		// Ping apenwarr.
-		start = time.Now()
-		resp, err = http.Get(apenwarrURL)
-		aLatency := time.Since(start).Milliseconds()
+// throughputRenderSlices mirrors renderSlices for throughput samples, which
+// carry two values (in/out bits-per-second) per timestamp instead of one.
+type throughputRenderSlices struct {
+	samples []throughput.Sample
+	xValues []time.Time
+	inYVals []float64
+	outYVal []float64
+}
+
+var throughputRenderSlicePool = sync.Pool{
+	New: func() interface{} {
+		return &throughputRenderSlices{
+			samples: make([]throughput.Sample, ringCapacity),
+			xValues: make([]time.Time, ringCapacity),
+			inYVals: make([]float64, ringCapacity),
+			outYVal: make([]float64, ringCapacity),
+		}
+	},
+}
+
+// runProbes loads the probe config and runs the scheduler until ctx is
+// cancelled, recording every result into results and buckets, and into
+// store too if one was configured via --storage.
+func runProbes(ctx context.Context, configPath string, store *storage.BoltStore) {
+	cfg := probe.DefaultConfig()
+	if configPath != "" {
+		loaded, err := probe.LoadConfig(configPath)
 		if err != nil {
-			log.Println("apenwarr ping error:", err)
-			aLatency = -1
-		} else {
-			resp.Body.Close()
+			log.Fatalf("load config: %v", err)
 		}
+		cfg = loaded
+	}
 
-		// Record the result.
-		resultsMu.Lock()
-		results = append(results, PingResult{
-			Timestamp:       now,
-			GstaticLatency:  gLatency,
-			ApenwarrLatency: aLatency,
-		})
-		// Keep only the most recent 60 data points.
-		if len(results) > 60 {
-			results = results[len(results)-60:]
+	scheduler, err := probe.NewScheduler(cfg.Targets)
+	if err != nil {
+		log.Fatalf("build scheduler: %v", err)
+	}
+
+	scheduler.Run(ctx, func(targetID string, r probe.Result) {
+		results.record(targetID, Sample{Timestamp: r.Timestamp, LatencyMs: r.LatencyMs})
+		buckets.record(targetID, r.Timestamp, r.LatencyMs)
+
+		if store != nil {
+			if err := store.Append(targetID, storage.Sample{Timestamp: r.Timestamp, LatencyMs: r.LatencyMs}); err != nil {
+				log.Println("storage append error:", err)
+			}
+		}
+	})
+}
+
+// throughputFlags holds the --snmp-*/--rest-* flag values used to build the
+// uplink throughput.Source, if any was configured.
+type throughputFlags struct {
+	snmpHost         string
+	snmpCommunity    string
+	snmpInOID        string
+	snmpOutOID       string
+	snmpHighCapacity bool
+	restURL          string
+	restToken        string
+	interval         time.Duration
+}
+
+// buildThroughputSource builds the configured throughput.Source, or nil if
+// neither SNMP nor REST flags were given.
+func (f throughputFlags) buildThroughputSource() throughput.Source {
+	switch {
+	case f.snmpHost != "":
+		return &throughput.SNMPSource{
+			Host:         f.snmpHost,
+			Community:    f.snmpCommunity,
+			InOID:        f.snmpInOID,
+			OutOID:       f.snmpOutOID,
+			HighCapacity: f.snmpHighCapacity,
+		}
+	case f.restURL != "":
+		return &throughput.RESTSource{URL: f.restURL, Token: f.restToken}
+	default:
+		return nil
+	}
+}
+
+// runThroughput polls the configured gateway throughput source until ctx is
+// cancelled, recording every sample into throughputBuf. It's a no-op if no
+// source was configured.
+func runThroughput(ctx context.Context, f throughputFlags) {
+	source := f.buildThroughputSource()
+	if source == nil {
+		return
+	}
+
+	poller := throughput.NewPoller(source, f.interval)
+	poller.Run(ctx, throughputBuf.Push)
+}
+
+// buildLatencyChart assembles the chart.Chart showing the raw per-second
+// latency line for every known target, returning ok=false once at least one
+// target has 2 or more data points isn't true for any of them. It is shared
+// by renderChart (Fyne canvas) and the HTTP /chart.png and /chart.svg
+// handlers.
+//
+// The returned release func must be called once the caller is done with
+// graph (i.e. after graph.Render), not before: graph's series reference the
+// renderSlices pulled from renderSlicePool directly, and returning a buffer
+// to the pool while a render is still reading from it lets a concurrent
+// Get hand that same backing array to someone else mid-render.
+func buildLatencyChart() (graph chart.Chart, release func(), ok bool) {
+	ids := results.ids()
+	if len(ids) == 0 || results.maxLen() < 2 {
+		return chart.Chart{}, func() {}, false
+	}
+
+	graph = chart.Chart{
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeValueFormatterWithFormat("15:04:05"),
+		},
+		YAxis: chart.YAxis{ValueFormatter: func(v interface{}) string {
+			return fmt.Sprintf("%.0f ms", v.(float64))
+		}},
+	}
+
+	var acquired []*renderSlices
+	release = func() {
+		for _, rs := range acquired {
+			renderSlicePool.Put(rs)
+		}
+	}
+
+	for i, id := range ids {
+		rs := renderSlicePool.Get().(*renderSlices)
+		acquired = append(acquired, rs)
+
+		n := results.snapshot(id, rs.samples)
+		if n < 2 {
+			continue
+		}
+
+		xValues := rs.xValues[:n]
+		yValues := rs.yValues[:n]
+		for j, sample := range rs.samples[:n] {
+			xValues[j] = sample.Timestamp
+			yValues[j] = float64(sample.LatencyMs)
 		}
-		resultsMu.Unlock()
 
-		time.Sleep(1 * time.Second)
+		graph.Series = append(graph.Series, chart.TimeSeries{
+			Name:    id,
+			Style:   chart.Style{StrokeColor: seriesColors[i%len(seriesColors)]},
+			XValues: xValues,
+			YValues: yValues,
+		})
+	}
+
+	if len(graph.Series) == 0 {
+		release()
+		return chart.Chart{}, func() {}, false
 	}
+
+	// Add a legend so that each target is clearly labeled.
+	graph.Elements = []chart.Renderable{
+		chart.Legend(&graph),
+	}
+	return graph, release, true
 }
 
-// renderChart creates a PNG image (decoded as image.Image) from the recorded data.
-// It only renders a chart if there are at least 2 data points.
+// renderChart creates a PNG image (decoded as image.Image) for the Fyne
+// canvas from buildLatencyChart's output.
 func renderChart() image.Image {
-	// Check for minimum data points.
-	resultsMu.Lock()
-	n := len(results)
-	resultsMu.Unlock()
-	if n < 2 {
+	graph, release, ok := buildLatencyChart()
+	if !ok {
 		return nil
 	}
+	defer release()
 
-	resultsMu.Lock()
-	defer resultsMu.Unlock()
+	buffer := bytes.NewBuffer(nil)
+	if err := graph.Render(chart.PNG, buffer); err != nil {
+		log.Println("Error rendering chart:", err)
+		return nil
+	}
+	img, _, err := image.Decode(buffer)
+	if err != nil {
+		log.Println("Error decoding chart image:", err)
+		return nil
+	}
+	return img
+}
 
-	// Prepare data for the time series.
-	xValues := make([]time.Time, n)
-	yValuesG := make([]float64, n)
-	yValuesA := make([]float64, n)
-	for i, r := range results {
-		xValues[i] = r.Timestamp
-		yValuesG[i] = float64(r.GstaticLatency)
-		yValuesA[i] = float64(r.ApenwarrLatency)
+// buildPercentileChart assembles the chart.Chart showing rolling
+// p50/p90/p95/p99 latency per target over percentileWindow, with a distinct
+// style per percentile series. Errors are excluded from the percentiles;
+// their count over the window is appended to the target's p50 legend entry
+// instead of being plotted. It is used by renderPercentileChart (Fyne
+// canvas); the HTTP /chart.png and /chart.svg handlers serve the latency
+// chart from buildLatencyChart instead.
+func buildPercentileChart() (graph chart.Chart, ok bool) {
+	ids := results.ids()
+	if len(ids) == 0 {
+		return chart.Chart{}, false
+	}
+
+	type percentileSeries struct {
+		p    float64
+		dash []float64
+	}
+	// Ordered (rather than ranged over a map) so series are appended in the
+	// same order every render, keeping legend and z-order stable.
+	percentiles := []percentileSeries{
+		{50, nil},
+		{90, []float64{5, 5}},
+		{95, []float64{3, 3}},
+		{99, []float64{1, 1}},
 	}
 
-	// Create a chart with two time series.
-	graph := chart.Chart{
+	graph = chart.Chart{
 		XAxis: chart.XAxis{
 			ValueFormatter: chart.TimeValueFormatterWithFormat("15:04:05"),
 		},
 		YAxis: chart.YAxis{ValueFormatter: func(v interface{}) string {
 			return fmt.Sprintf("%.0f ms", v.(float64))
 		}},
+	}
+
+	haveSeries := false
+	for i, id := range ids {
+		xValues, p50, p90, p95, p99, errCount := buckets.series(id)
+		if len(xValues) < 2 {
+			continue
+		}
+		haveSeries = true
+
+		strokeColor := seriesColors[i%len(seriesColors)]
+		byPercentile := map[float64][]float64{50: p50, 90: p90, 95: p95, 99: p99}
+		for _, ps := range percentiles {
+			name := fmt.Sprintf("%s p%.0f", id, ps.p)
+			if ps.p == 50 && errCount > 0 {
+				name = fmt.Sprintf("%s (%d errors)", name, errCount)
+			}
+			graph.Series = append(graph.Series, chart.TimeSeries{
+				Name:    name,
+				Style:   chart.Style{StrokeColor: strokeColor, StrokeDashArray: ps.dash},
+				XValues: xValues,
+				YValues: byPercentile[ps.p],
+			})
+		}
+	}
+	if !haveSeries {
+		return chart.Chart{}, false
+	}
+
+	graph.Elements = []chart.Renderable{
+		chart.Legend(&graph),
+	}
+	return graph, true
+}
+
+// renderPercentileChart creates a PNG image for the Fyne canvas from
+// buildPercentileChart's output.
+func renderPercentileChart() image.Image {
+	graph, ok := buildPercentileChart()
+	if !ok {
+		return nil
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	if err := graph.Render(chart.PNG, buffer); err != nil {
+		log.Println("Error rendering percentile chart:", err)
+		return nil
+	}
+	img, _, err := image.Decode(buffer)
+	if err != nil {
+		log.Println("Error decoding percentile chart image:", err)
+		return nil
+	}
+	return img
+}
+
+// buildThroughputChart assembles the chart.Chart showing gateway uplink
+// in/out bits-per-second, so it can be visually correlated against the
+// latency charts above. Returns ok=false if no throughput source is
+// configured or not enough samples have been collected yet.
+//
+// As with buildLatencyChart, the returned release func must only be called
+// after the caller has finished rendering graph: the series reference the
+// pooled renderSlices' backing arrays directly.
+func buildThroughputChart() (graph chart.Chart, release func(), ok bool) {
+	rs := throughputRenderSlicePool.Get().(*throughputRenderSlices)
+	release = func() { throughputRenderSlicePool.Put(rs) }
+
+	n := throughputBuf.Snapshot(rs.samples)
+	if n < 2 {
+		release()
+		return chart.Chart{}, func() {}, false
+	}
+
+	xValues := rs.xValues[:n]
+	inYVals := rs.inYVals[:n]
+	outYVal := rs.outYVal[:n]
+	for i, s := range rs.samples[:n] {
+		xValues[i] = s.Timestamp
+		inYVals[i] = s.InBps
+		outYVal[i] = s.OutBps
+	}
+
+	graph = chart.Chart{
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeValueFormatterWithFormat("15:04:05"),
+		},
+		YAxis: chart.YAxis{ValueFormatter: func(v interface{}) string {
+			return fmt.Sprintf("%.1f Mbps", v.(float64)/1e6)
+		}},
 		Series: []chart.Series{
 			chart.TimeSeries{
-				Name:    "gstatic" + " (" + gstaticURL + ")", // Label the series with the URL.
+				Name:    "downlink",
+				Style:   chart.Style{StrokeColor: chart.ColorBlue},
 				XValues: xValues,
-				YValues: yValuesG,
+				YValues: inYVals,
 			},
 			chart.TimeSeries{
-				Name:    "apenwarr" + " (" + apenwarrURL + ")", // Label the series with the URL.
+				Name:    "uplink",
+				Style:   chart.Style{StrokeColor: chart.ColorGreen},
 				XValues: xValues,
-				YValues: yValuesA,
+				YValues: outYVal,
 			},
 		},
 	}
-	// Add a legend so that each graph is clearly labeled.
 	graph.Elements = []chart.Renderable{
 		chart.Legend(&graph),
 	}
+	return graph, release, true
+}
+
+// renderThroughputChart creates a PNG image for the Fyne canvas from
+// buildThroughputChart's output.
+func renderThroughputChart() image.Image {
+	graph, release, ok := buildThroughputChart()
+	if !ok {
+		return nil
+	}
+	defer release()
 
-	// Render the chart into a PNG image.
 	buffer := bytes.NewBuffer(nil)
 	if err := graph.Render(chart.PNG, buffer); err != nil {
-		log.Println("Error rendering chart:", err)
+		log.Println("Error rendering throughput chart:", err)
 		return nil
 	}
 	img, _, err := image.Decode(buffer)
 	if err != nil {
-		log.Println("Error decoding chart image:", err)
+		log.Println("Error decoding throughput chart image:", err)
 		return nil
 	}
 	return img
@@ -155,6 +423,45 @@ func renderPlaceholder() image.Image {
 }
 
 func main() {
+	configPath := flag.String("config", "", "path to a YAML probe target config (defaults to the built-in gstatic/apenwarr HTTP checks)")
+	listenAddr := flag.String("listen", "", "address to serve /chart.png, /chart.svg, /metrics and /results.json on (disabled if empty)")
+	storagePath := flag.String("storage", "", "path to a BoltDB file to append every live sample to (disabled if empty)")
+
+	replayPath := flag.String("replay", "", "path to a BoltDB file to replay instead of probing live")
+	replayFrom := flag.String("from", "", "RFC3339 start time for --replay (default: earliest sample on disk)")
+	replayTo := flag.String("to", "", "RFC3339 end time for --replay (default: now)")
+	exportPath := flag.String("export", "", "with --replay, export the chart to this file (.png or .svg; .pdf is not supported, go-chart has no PDF renderer) instead of opening a window")
+
+	var tpFlags throughputFlags
+	flag.StringVar(&tpFlags.snmpHost, "snmp-host", "", "gateway host:port to poll via SNMP for uplink throughput (disabled if empty)")
+	flag.StringVar(&tpFlags.snmpCommunity, "snmp-community", "public", "SNMP community string")
+	flag.StringVar(&tpFlags.snmpInOID, "snmp-in-oid", ".1.3.6.1.2.1.2.2.1.10.1", "SNMP OID for inbound octet counter (default: ifInOctets.1)")
+	flag.StringVar(&tpFlags.snmpOutOID, "snmp-out-oid", ".1.3.6.1.2.1.2.2.1.16.1", "SNMP OID for outbound octet counter (default: ifOutOctets.1)")
+	flag.BoolVar(&tpFlags.snmpHighCapacity, "snmp-high-capacity", false, "treat SNMP counters as 64-bit ifHCInOctets/ifHCOutOctets instead of 32-bit")
+	flag.StringVar(&tpFlags.restURL, "rest-url", "", "ntopng/pfsense-style REST endpoint to poll for uplink throughput (disabled if empty)")
+	flag.StringVar(&tpFlags.restToken, "rest-token", "", "bearer token for --rest-url")
+	flag.DurationVar(&tpFlags.interval, "throughput-interval", time.Second, "how often to poll the configured throughput source")
+	flag.Parse()
+
+	if *replayPath != "" {
+		runReplay(*replayPath, *replayFrom, *replayTo, *exportPath)
+		return
+	}
+
+	var store *storage.BoltStore
+	if *storagePath != "" {
+		opened, err := storage.Open(*storagePath)
+		if err != nil {
+			log.Fatalf("open storage: %v", err)
+		}
+		store = opened
+		defer store.Close()
+	}
+
+	if *listenAddr != "" {
+		go serveHTTP(*listenAddr)
+	}
+
 	// Create a new Fyne application.
 	myApp := app.New()
 	myWindow := myApp.NewWindow("Native Go Blip")
@@ -170,19 +477,36 @@ func main() {
 
 	// Overlay the image and text.
 	content := container.NewStack(chartImage, loadingText)
-	myWindow.SetContent(content)
+
+	// Second panel: rolling p50/p90/p95/p99 latency.
+	percentileImage := canvas.NewImageFromImage(nil)
+	percentileImage.FillMode = canvas.ImageFillContain
+	percentileContent := container.NewStack(percentileImage)
+
+	// Third panel: gateway uplink throughput, for correlating with latency.
+	throughputImage := canvas.NewImageFromImage(nil)
+	throughputImage.FillMode = canvas.ImageFillContain
+	throughputContent := container.NewStack(throughputImage)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Latency", content),
+		container.NewTabItem("Percentiles", percentileContent),
+		container.NewTabItem("Throughput", throughputContent),
+	)
+	myWindow.SetContent(tabs)
 	myWindow.Resize(fyne.NewSize(800, 600))
 
-	// Start the pinging process.
-	go doPings()
+	// Start the probe scheduler and the throughput poller (a no-op if no
+	// throughput source was configured).
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runProbes(ctx, *configPath, store)
+	go runThroughput(ctx, tpFlags)
 
 	// Update loop: if there are not enough data points, show the placeholder.
 	go func() {
 		for {
-			resultsMu.Lock()
-			n := len(results)
-			resultsMu.Unlock()
-			if n < 2 {
+			if results.maxLen() < 2 {
 				// While waiting for data, show the placeholder.
 				loadingText.Show()
 				chartImage.Image = renderPlaceholder() // A blank background (optionally replace with a drawn "Loading..." image).
@@ -194,6 +518,14 @@ func main() {
 					chartImage.Image = img
 					chartImage.Refresh()
 				}
+				if img := renderPercentileChart(); img != nil {
+					percentileImage.Image = img
+					percentileImage.Refresh()
+				}
+			}
+			if img := renderThroughputChart(); img != nil {
+				throughputImage.Image = img
+				throughputImage.Refresh()
 			}
 			time.Sleep(1 * time.Second)
 		}
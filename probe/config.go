@@ -0,0 +1,92 @@
+package probe
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Type identifies which Prober implementation a Target uses.
+type Type string
+
+const (
+	TypeHTTP Type = "http"
+	TypeICMP Type = "icmp"
+	TypeTCP  Type = "tcp"
+	TypeDNS  Type = "dns"
+)
+
+// Target describes one thing to probe: where, how, and how often.
+type Target struct {
+	ID       string        `yaml:"id"`
+	Type     Type          `yaml:"type"`
+	Address  string        `yaml:"address"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+
+	// HTTP-specific.
+	Method         string `yaml:"method,omitempty"`
+	ExpectedStatus int    `yaml:"expected_status,omitempty"`
+
+	// DNS-specific.
+	RecordType string `yaml:"record_type,omitempty"`
+	Resolver   string `yaml:"resolver,omitempty"`
+}
+
+// Config is the top-level document loaded from the --config file.
+type Config struct {
+	Targets []Target `yaml:"targets"`
+}
+
+// DefaultConfig mirrors go-blip's original hard-coded gstatic/apenwarr HTTP
+// checks, and is used when no --config flag is given.
+func DefaultConfig() Config {
+	return Config{
+		Targets: []Target{
+			{ID: "gstatic", Type: TypeHTTP, Address: "https://www.gstatic.com/generate_204", Interval: time.Second, Timeout: 5 * time.Second, Method: "GET"},
+			{ID: "apenwarr", Type: TypeHTTP, Address: "https://apenwarr.ca", Interval: time.Second, Timeout: 5 * time.Second, Method: "GET"},
+		},
+	}
+}
+
+// LoadConfig reads and parses a YAML target list from path, filling in the
+// default interval/timeout for any target that omits them.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config: %w", err)
+	}
+
+	for i := range cfg.Targets {
+		if cfg.Targets[i].Interval == 0 {
+			cfg.Targets[i].Interval = time.Second
+		}
+		if cfg.Targets[i].Timeout == 0 {
+			cfg.Targets[i].Timeout = 5 * time.Second
+		}
+	}
+	return cfg, nil
+}
+
+// NewProber builds the Prober implementation configured for a Target.
+func NewProber(t Target) (Prober, error) {
+	switch t.Type {
+	case TypeHTTP:
+		return NewHTTPProber(t), nil
+	case TypeICMP:
+		return NewICMPProber(t), nil
+	case TypeTCP:
+		return NewTCPProber(t), nil
+	case TypeDNS:
+		return NewDNSProber(t), nil
+	default:
+		return nil, fmt.Errorf("target %q: unknown probe type %q", t.ID, t.Type)
+	}
+}
@@ -0,0 +1,50 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSProber times an A/AAAA query for the target's address against a
+// configured resolver (defaulting to Cloudflare's 1.1.1.1).
+type DNSProber struct {
+	target Target
+	client *dns.Client
+}
+
+// NewDNSProber builds a DNSProber for t.
+func NewDNSProber(t Target) *DNSProber {
+	return &DNSProber{
+		target: t,
+		client: &dns.Client{Timeout: t.Timeout},
+	}
+}
+
+func (p *DNSProber) Probe(ctx context.Context) Result {
+	now := time.Now()
+
+	qtype := dns.TypeA
+	if p.target.RecordType == "AAAA" {
+		qtype = dns.TypeAAAA
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(p.target.Address), qtype)
+
+	resolver := p.target.Resolver
+	if resolver == "" {
+		resolver = "1.1.1.1:53"
+	}
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+
+	_, rtt, err := p.client.ExchangeContext(ctx, msg, resolver)
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+	return Result{Timestamp: now, LatencyMs: rtt.Milliseconds()}
+}
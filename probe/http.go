@@ -0,0 +1,48 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPProber times an HTTP request and, if ExpectedStatus is set, treats a
+// mismatched response status as a probe error.
+type HTTPProber struct {
+	target Target
+	client *http.Client
+}
+
+// NewHTTPProber builds an HTTPProber for t, defaulting Method to GET.
+func NewHTTPProber(t Target) *HTTPProber {
+	if t.Method == "" {
+		t.Method = http.MethodGet
+	}
+	return &HTTPProber{
+		target: t,
+		client: &http.Client{Timeout: t.Timeout},
+	}
+}
+
+func (p *HTTPProber) Probe(ctx context.Context) Result {
+	now := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, p.target.Method, p.target.Address, nil)
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if p.target.ExpectedStatus != 0 && resp.StatusCode != p.target.ExpectedStatus {
+		return Result{Timestamp: now, LatencyMs: -1, Err: fmt.Errorf("unexpected status %d (want %d)", resp.StatusCode, p.target.ExpectedStatus)}
+	}
+	return Result{Timestamp: now, LatencyMs: latency}
+}
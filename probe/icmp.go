@@ -0,0 +1,89 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// ICMPProber sends a raw ICMP echo request and times the reply. It requires
+// permission to open a raw socket (root, or CAP_NET_RAW on Linux).
+type ICMPProber struct {
+	target Target
+	id     int
+}
+
+var icmpSeq int32
+
+// NewICMPProber builds an ICMPProber for t.
+func NewICMPProber(t Target) *ICMPProber {
+	return &ICMPProber{target: t, id: os.Getpid() & 0xffff}
+}
+
+func (p *ICMPProber) Probe(ctx context.Context) Result {
+	now := time.Now()
+
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: fmt.Errorf("listen icmp: %w", err)}
+	}
+	defer conn.Close()
+
+	dst, err := net.ResolveIPAddr("ip4", p.target.Address)
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+
+	// Masked to 16 bits because icmp.Echo.Marshal writes Seq as a uint16; a
+	// bare int comparison against the post-wrap wire value would never match
+	// again once the counter passes 65535.
+	seq := int(atomic.AddInt32(&icmpSeq, 1)) & 0xffff
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Code: 0,
+		Body: &icmp.Echo{ID: p.id, Seq: seq, Data: []byte("go-blip")},
+	}
+	wb, err := msg.Marshal(nil)
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+
+	conn.SetDeadline(time.Now().Add(p.target.Timeout))
+
+	start := time.Now()
+	if _, err := conn.WriteTo(wb, dst); err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+
+	// The raw socket is shared with every other in-flight ICMP probe on this
+	// host, so replies to other pings (or stray echoes from unrelated seq
+	// numbers) can land here too. Keep reading until we see our own ID/Seq
+	// come back from the peer we pinged, or the deadline set above fires.
+	rb := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(rb)
+		if err != nil {
+			return Result{Timestamp: now, LatencyMs: -1, Err: err}
+		}
+
+		reply, err := icmp.ParseMessage(1, rb[:n])
+		if err != nil {
+			return Result{Timestamp: now, LatencyMs: -1, Err: err}
+		}
+		if reply.Type != ipv4.ICMPTypeEchoReply || peer.String() != dst.String() {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.ID != p.id || echo.Seq != seq {
+			continue
+		}
+
+		return Result{Timestamp: now, LatencyMs: time.Since(start).Milliseconds()}
+	}
+}
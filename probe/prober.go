@@ -0,0 +1,22 @@
+// Package probe defines the Prober abstraction used to measure latency (or
+// failure) against a configured target, independent of how the target is
+// reached (HTTP, ICMP, TCP, DNS).
+package probe
+
+import (
+	"context"
+	"time"
+)
+
+// Result is a single probe outcome: either a measured latency or an error.
+type Result struct {
+	Timestamp time.Time
+	LatencyMs int64 // milliseconds; -1 if Err is set
+	Err       error
+}
+
+// Prober performs a single probe against a target and reports how long it
+// took, or why it failed.
+type Prober interface {
+	Probe(ctx context.Context) Result
+}
@@ -0,0 +1,57 @@
+package probe
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Scheduler runs one Prober per configured Target, each on its own
+// goroutine firing at its own interval, and reports every Result through a
+// caller-supplied callback.
+type Scheduler struct {
+	targets []Target
+}
+
+// NewScheduler builds a Scheduler for the given targets. It fails fast if
+// any target has an unrecognized probe type.
+func NewScheduler(targets []Target) (*Scheduler, error) {
+	for _, t := range targets {
+		if _, err := NewProber(t); err != nil {
+			return nil, err
+		}
+	}
+	return &Scheduler{targets: targets}, nil
+}
+
+// Run starts one goroutine per target and blocks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context, onResult func(targetID string, r Result)) {
+	for _, t := range s.targets {
+		go s.runTarget(ctx, t, onResult)
+	}
+	<-ctx.Done()
+}
+
+func (s *Scheduler) runTarget(ctx context.Context, t Target, onResult func(targetID string, r Result)) {
+	prober, err := NewProber(t)
+	if err != nil {
+		log.Printf("probe %s: %v", t.ID, err)
+		return
+	}
+
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r := prober.Probe(ctx)
+			if r.Err != nil {
+				log.Printf("probe %s error: %v", t.ID, r.Err)
+			}
+			onResult(t.ID, r)
+		}
+	}
+}
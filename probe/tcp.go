@@ -0,0 +1,33 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// TCPProber times a raw TCP dial/handshake against the target's "host:port"
+// address.
+type TCPProber struct {
+	target Target
+}
+
+// NewTCPProber builds a TCPProber for t.
+func NewTCPProber(t Target) *TCPProber {
+	return &TCPProber{target: t}
+}
+
+func (p *TCPProber) Probe(ctx context.Context) Result {
+	now := time.Now()
+	dialer := net.Dialer{Timeout: p.target.Timeout}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", p.target.Address)
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		return Result{Timestamp: now, LatencyMs: -1, Err: err}
+	}
+	conn.Close()
+
+	return Result{Timestamp: now, LatencyMs: latency}
+}
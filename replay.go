@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/canvas"
+
+	chart "github.com/wcharczuk/go-chart/v2"
+
+	"github.com/Ivan-Feofanov/go-blip/storage"
+)
+
+// runReplay reconstructs results from a storage file between fromStr and
+// toStr (RFC3339, defaulting to the full range on disk) and either exports
+// the resulting latency chart to exportPath, or opens a static window
+// showing it if exportPath is empty.
+func runReplay(path, fromStr, toStr, exportPath string) {
+	store, err := storage.Open(path)
+	if err != nil {
+		log.Fatalf("open storage: %v", err)
+	}
+	defer store.Close()
+
+	from, to := parseReplayRange(fromStr, toStr)
+
+	samples, err := store.Query(from, to)
+	if err != nil {
+		log.Fatalf("query storage: %v", err)
+	}
+
+	// Built straight from the queried samples rather than fed through the
+	// live 3600-entry RingBuffer: a replay window wider than an hour would
+	// otherwise silently lose everything but its final hour.
+	graph, ok := buildReplayChart(samples)
+	if !ok {
+		log.Fatalf("no samples found between %s and %s", from, to)
+	}
+
+	if exportPath != "" {
+		if err := exportChart(graph, exportPath); err != nil {
+			log.Fatalf("export chart: %v", err)
+		}
+		return
+	}
+
+	showStaticChart(graph)
+}
+
+// buildReplayChart assembles the latency chart.Chart directly from a
+// storage query result, one series per target, ordered by target ID for a
+// stable legend. Unlike buildLatencyChart it isn't bounded by ringCapacity,
+// so a replay window of any length renders in full.
+func buildReplayChart(samples map[string][]storage.Sample) (graph chart.Chart, ok bool) {
+	ids := make([]string, 0, len(samples))
+	for id := range samples {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	graph = chart.Chart{
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeValueFormatterWithFormat("15:04:05"),
+		},
+		YAxis: chart.YAxis{ValueFormatter: func(v interface{}) string {
+			return fmt.Sprintf("%.0f ms", v.(float64))
+		}},
+	}
+
+	for i, id := range ids {
+		targetSamples := samples[id]
+		if len(targetSamples) < 2 {
+			continue
+		}
+
+		xValues := make([]time.Time, len(targetSamples))
+		yValues := make([]float64, len(targetSamples))
+		for j, s := range targetSamples {
+			xValues[j] = s.Timestamp
+			yValues[j] = float64(s.LatencyMs)
+		}
+
+		graph.Series = append(graph.Series, chart.TimeSeries{
+			Name:    id,
+			Style:   chart.Style{StrokeColor: seriesColors[i%len(seriesColors)]},
+			XValues: xValues,
+			YValues: yValues,
+		})
+	}
+
+	if len(graph.Series) == 0 {
+		return chart.Chart{}, false
+	}
+
+	graph.Elements = []chart.Renderable{
+		chart.Legend(&graph),
+	}
+	return graph, true
+}
+
+func parseReplayRange(fromStr, toStr string) (from, to time.Time) {
+	from = time.Unix(0, 0)
+	to = time.Now()
+	if fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			log.Fatalf("parse --from: %v", err)
+		}
+		from = parsed
+	}
+	if toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			log.Fatalf("parse --to: %v", err)
+		}
+		to = parsed
+	}
+	return from, to
+}
+
+// exportChart renders graph to path, picking PNG or SVG based on its
+// extension. go-chart has no PDF renderer, so a .pdf path is rejected
+// rather than silently producing the wrong format.
+func exportChart(graph chart.Chart, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch ext := filepath.Ext(path); ext {
+	case ".png":
+		return graph.Render(chart.PNG, f)
+	case ".svg":
+		return graph.Render(chart.SVG, f)
+	default:
+		return fmt.Errorf("unsupported export extension %q (want .png or .svg)", ext)
+	}
+}
+
+// showStaticChart opens a minimal window showing a single already-rendered
+// chart, with no live update loop.
+func showStaticChart(graph chart.Chart) {
+	buffer := bytes.NewBuffer(nil)
+	if err := graph.Render(chart.PNG, buffer); err != nil {
+		log.Fatalf("render chart: %v", err)
+	}
+	img, _, err := image.Decode(buffer)
+	if err != nil {
+		log.Fatalf("decode chart: %v", err)
+	}
+
+	myApp := app.New()
+	myWindow := myApp.NewWindow("Native Go Blip — replay")
+
+	chartImage := canvas.NewImageFromImage(img)
+	chartImage.FillMode = canvas.ImageFillContain
+	myWindow.SetContent(chartImage)
+	myWindow.Resize(fyne.NewSize(800, 600))
+	myWindow.ShowAndRun()
+}
@@ -0,0 +1,50 @@
+package main
+
+import "time"
+
+// ringCapacity is how many seconds of history a target's ring buffer
+// retains (3600 seconds = 1 hour). The percentile chart keeps its own,
+// shorter window (percentileWindow).
+const ringCapacity = 3600
+
+// Sample is one probe result recorded for a single target.
+type Sample struct {
+	Timestamp time.Time
+	LatencyMs int64 // milliseconds; -1 if the probe errored
+}
+
+// RingBuffer stores the last ringCapacity Samples for one target in a
+// pre-allocated array, overwriting the oldest entry once full. It never
+// reslices or reallocates on the hot path.
+type RingBuffer struct {
+	data    [ringCapacity]Sample
+	Current int // next write position
+	count   int // number of valid entries (caps out at ringCapacity)
+}
+
+// Push records a new sample, overwriting the oldest entry once the buffer
+// is full.
+func (r *RingBuffer) Push(s Sample) {
+	r.data[r.Current] = s
+	r.Current = (r.Current + 1) % ringCapacity
+	if r.count < ringCapacity {
+		r.count++
+	}
+}
+
+// Len returns the number of valid entries currently stored.
+func (r *RingBuffer) Len() int {
+	return r.count
+}
+
+// Snapshot copies the valid entries, oldest first, into dst and returns the
+// number written. dst must have length >= r.Len(); callers typically obtain
+// dst from the render slice pool sized to ringCapacity.
+func (r *RingBuffer) Snapshot(dst []Sample) int {
+	n := r.count
+	start := (r.Current - n + ringCapacity) % ringCapacity
+	for i := 0; i < n; i++ {
+		dst[i] = r.data[(start+i)%ringCapacity]
+	}
+	return n
+}
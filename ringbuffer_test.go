@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRingBufferPushAndSnapshot(t *testing.T) {
+	var rb RingBuffer
+
+	rb.Push(Sample{Timestamp: time.Unix(1, 0), LatencyMs: 10})
+	rb.Push(Sample{Timestamp: time.Unix(2, 0), LatencyMs: 20})
+	rb.Push(Sample{Timestamp: time.Unix(3, 0), LatencyMs: 30})
+
+	if got := rb.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+
+	dst := make([]Sample, rb.Len())
+	n := rb.Snapshot(dst)
+	if n != 3 {
+		t.Fatalf("Snapshot() returned %d, want 3", n)
+	}
+	want := []int64{10, 20, 30}
+	for i, w := range want {
+		if dst[i].LatencyMs != w {
+			t.Errorf("dst[%d].LatencyMs = %d, want %d", i, dst[i].LatencyMs, w)
+		}
+	}
+}
+
+func TestRingBufferWraparound(t *testing.T) {
+	var rb RingBuffer
+
+	// Push one more sample than the buffer can hold, so the oldest entry
+	// (LatencyMs 0) gets overwritten.
+	for i := 0; i < ringCapacity+1; i++ {
+		rb.Push(Sample{Timestamp: time.Unix(int64(i), 0), LatencyMs: int64(i)})
+	}
+
+	if got := rb.Len(); got != ringCapacity {
+		t.Fatalf("Len() = %d, want %d", got, ringCapacity)
+	}
+
+	dst := make([]Sample, rb.Len())
+	n := rb.Snapshot(dst)
+	if n != ringCapacity {
+		t.Fatalf("Snapshot() returned %d, want %d", n, ringCapacity)
+	}
+	if dst[0].LatencyMs != 1 {
+		t.Errorf("oldest surviving sample LatencyMs = %d, want 1", dst[0].LatencyMs)
+	}
+	if dst[len(dst)-1].LatencyMs != ringCapacity {
+		t.Errorf("newest sample LatencyMs = %d, want %d", dst[len(dst)-1].LatencyMs, ringCapacity)
+	}
+}
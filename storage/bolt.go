@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// BoltStore persists Samples to a local BoltDB file, one bucket per probe
+// target, keyed by the sample's timestamp (big-endian Unix nanoseconds) so
+// a range of keys can be scanned directly to answer "what did this target
+// look like between T1 and T2".
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+// Open opens (or creates) a BoltDB file at path.
+func Open(path string) (*BoltStore, error) {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open storage: %w", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Close closes the underlying BoltDB file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// Append persists one sample for targetID.
+func (s *BoltStore) Append(targetID string, sample Sample) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(targetID))
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, uint64(sample.Timestamp.UnixNano()))
+		val := make([]byte, 8)
+		binary.BigEndian.PutUint64(val, uint64(sample.LatencyMs))
+		return bucket.Put(key, val)
+	})
+}
+
+// Query returns every target's samples with a timestamp in [from, to],
+// oldest first, keyed by target ID.
+func (s *BoltStore) Query(from, to time.Time) (map[string][]Sample, error) {
+	fromKey := make([]byte, 8)
+	binary.BigEndian.PutUint64(fromKey, uint64(from.UnixNano()))
+	toKey := make([]byte, 8)
+	binary.BigEndian.PutUint64(toKey, uint64(to.UnixNano()))
+
+	out := make(map[string][]Sample)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			targetID := string(name)
+			c := bucket.Cursor()
+			for k, v := c.Seek(fromKey); k != nil && bytes.Compare(k, toKey) <= 0; k, v = c.Next() {
+				out[targetID] = append(out[targetID], Sample{
+					Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(k))),
+					LatencyMs: int64(binary.BigEndian.Uint64(v)),
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query storage: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,11 @@
+// Package storage persists probe samples to disk and answers range queries
+// over them, so a session can be replayed later.
+package storage
+
+import "time"
+
+// Sample is one probe result persisted for a single target.
+type Sample struct {
+	Timestamp time.Time
+	LatencyMs int64 // milliseconds; -1 if the probe errored
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// targetStore holds one RingBuffer of Samples per probe target, keyed by
+// target ID, along with a running count of probe errors for that target.
+type targetStore struct {
+	mu          sync.Mutex
+	buffers     map[string]*RingBuffer
+	errorTotals map[string]int64
+}
+
+func newTargetStore() *targetStore {
+	return &targetStore{
+		buffers:     make(map[string]*RingBuffer),
+		errorTotals: make(map[string]int64),
+	}
+}
+
+// record appends a sample for the given target, creating its buffer on
+// first use, and tallies it as an error if its latency is negative.
+func (s *targetStore) record(id string, sample Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rb, ok := s.buffers[id]
+	if !ok {
+		rb = &RingBuffer{}
+		s.buffers[id] = rb
+	}
+	rb.Push(sample)
+	if sample.LatencyMs < 0 {
+		s.errorTotals[id]++
+	}
+}
+
+// errorTotal returns the cumulative number of errored probes recorded for
+// the given target.
+func (s *targetStore) errorTotal(id string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.errorTotals[id]
+}
+
+// latest returns the most recently recorded sample for the given target, or
+// false if none has been recorded yet.
+func (s *targetStore) latest(id string) (Sample, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rb, ok := s.buffers[id]
+	if !ok || rb.Len() == 0 {
+		return Sample{}, false
+	}
+	idx := (rb.Current - 1 + ringCapacity) % ringCapacity
+	return rb.data[idx], true
+}
+
+// ids returns all known target IDs, sorted for stable chart ordering.
+func (s *targetStore) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.buffers))
+	for id := range s.buffers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// maxLen returns the largest sample count across all targets, used to
+// decide whether there's enough data to render a chart yet.
+func (s *targetStore) maxLen() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	max := 0
+	for _, rb := range s.buffers {
+		if n := rb.Len(); n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// snapshot copies the given target's valid samples into dst and returns the
+// number written, or 0 if the target is unknown.
+func (s *targetStore) snapshot(id string, dst []Sample) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rb, ok := s.buffers[id]
+	if !ok {
+		return 0
+	}
+	return rb.Snapshot(dst)
+}
+
+// percentileWindow is how far back latency samples are kept for the rolling
+// percentile chart.
+const percentileWindow = 5 * time.Minute
+
+// latencyBucket collects every latency sample recorded for one target
+// within one second, so percentiles can be computed per second.
+type latencyBucket struct {
+	second  time.Time
+	samples []int64
+	errs    int
+}
+
+// bucketStore holds the rolling percentileWindow of latencyBuckets per
+// target, keyed by target ID.
+type bucketStore struct {
+	mu      sync.Mutex
+	buckets map[string][]latencyBucket
+}
+
+func newBucketStore() *bucketStore {
+	return &bucketStore{buckets: make(map[string][]latencyBucket)}
+}
+
+// record appends a single latency sample (or error, when latencyMs < 0) to
+// the given target's bucket for the current second, creating it if needed,
+// and trims buckets older than percentileWindow.
+func (s *bucketStore) record(id string, now time.Time, latencyMs int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets := s.buckets[id]
+	second := now.Truncate(time.Second)
+	if len(buckets) == 0 || !buckets[len(buckets)-1].second.Equal(second) {
+		buckets = append(buckets, latencyBucket{second: second})
+	}
+	b := &buckets[len(buckets)-1]
+	if latencyMs < 0 {
+		b.errs++
+	} else {
+		b.samples = append(b.samples, latencyMs)
+	}
+
+	cutoff := now.Add(-percentileWindow)
+	i := 0
+	for i < len(buckets) && buckets[i].second.Before(cutoff) {
+		i++
+	}
+	s.buckets[id] = buckets[i:]
+}
+
+// percentile returns the p-th percentile (0-100) of sorted samples, linearly
+// interpolating between the two nearest ranks. samples must already be
+// sorted ascending.
+func percentile(sorted []int64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p / 100) * float64(len(sorted)-1)
+	lo := int(rank)
+	hi := lo + 1
+	if hi >= len(sorted) {
+		return float64(sorted[lo])
+	}
+	frac := rank - float64(lo)
+	return float64(sorted[lo])*(1-frac) + float64(sorted[hi])*frac
+}
+
+// series computes p50/p90/p95/p99 time series for one target's per-bucket
+// samples, along with the number of error samples seen.
+func (s *bucketStore) series(id string) (xValues []time.Time, p50, p90, p95, p99 []float64, errCount int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.buckets[id] {
+		errCount += b.errs
+
+		samples := append([]int64(nil), b.samples...)
+		if len(samples) == 0 {
+			continue
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		xValues = append(xValues, b.second)
+		p50 = append(p50, percentile(samples, 50))
+		p90 = append(p90, percentile(samples, 90))
+		p95 = append(p95, percentile(samples, 95))
+		p99 = append(p99, percentile(samples, 99))
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	sorted := []int64{10, 20, 30, 40, 50}
+
+	cases := []struct {
+		name string
+		p    float64
+		want float64
+	}{
+		{"p0", 0, 10},
+		{"p50", 50, 30},
+		{"p100", 100, 50},
+		{"p25 interpolated", 25, 20},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := percentile(sorted, c.p); got != c.want {
+				t.Errorf("percentile(%v, %v) = %v, want %v", sorted, c.p, got, c.want)
+			}
+		})
+	}
+}
+
+func TestPercentileEmpty(t *testing.T) {
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil, 50) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,50 @@
+package throughput
+
+import "sync"
+
+// capacity is how many seconds of throughput history Buffer retains (3600
+// seconds = 1 hour), matching the ping probes' ring buffer.
+const capacity = 3600
+
+// Buffer is a fixed-size ring buffer of throughput Samples, mirroring the
+// ring buffer the ping probes use for latency.
+type Buffer struct {
+	mu      sync.Mutex
+	data    [capacity]Sample
+	current int
+	count   int
+}
+
+// Push records a new sample, overwriting the oldest entry once the buffer
+// is full.
+func (b *Buffer) Push(s Sample) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data[b.current] = s
+	b.current = (b.current + 1) % capacity
+	if b.count < capacity {
+		b.count++
+	}
+}
+
+// Len returns the number of valid entries currently stored.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// Snapshot copies the valid entries, oldest first, into dst and returns the
+// number written. dst must have length >= b.Len().
+func (b *Buffer) Snapshot(dst []Sample) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.count
+	start := (b.current - n + capacity) % capacity
+	for i := 0; i < n; i++ {
+		dst[i] = b.data[(start+i)%capacity]
+	}
+	return n
+}
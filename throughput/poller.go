@@ -0,0 +1,88 @@
+package throughput
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Sample is one throughput measurement: bits/sec in and out, computed by
+// differencing successive counter reads.
+type Sample struct {
+	Timestamp time.Time
+	InBps     float64
+	OutBps    float64
+}
+
+// reading is a single raw counter read, kept so the next tick can compute a
+// delta against it.
+type reading struct {
+	at                  time.Time
+	inOctets, outOctets uint64
+}
+
+// Poller periodically reads a Source's counters and reports the resulting
+// bits/sec Sample through a callback, once enough reads have been taken to
+// compute a delta.
+type Poller struct {
+	source   Source
+	interval time.Duration
+}
+
+// NewPoller builds a Poller that reads source every interval.
+func NewPoller(source Source, interval time.Duration) *Poller {
+	return &Poller{source: source, interval: interval}
+}
+
+// Run polls until ctx is cancelled, invoking onSample with each computed
+// throughput reading.
+func (p *Poller) Run(ctx context.Context, onSample func(Sample)) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	var last *reading
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			in, out, err := p.source.ReadCounters(ctx)
+			if err != nil {
+				log.Println("throughput poll error:", err)
+				continue
+			}
+			now := time.Now()
+
+			if last != nil {
+				elapsed := now.Sub(last.at).Seconds()
+				if elapsed > 0 {
+					inDelta := wrapDelta(last.inOctets, in, p.source.CounterBits())
+					outDelta := wrapDelta(last.outOctets, out, p.source.CounterBits())
+					onSample(Sample{
+						Timestamp: now,
+						InBps:     float64(inDelta) * 8 / elapsed,
+						OutBps:    float64(outDelta) * 8 / elapsed,
+					})
+				}
+			}
+			last = &reading{at: now, inOctets: in, outOctets: out}
+		}
+	}
+}
+
+// wrapDelta computes cur-prev. For counters narrower than 64 bits (e.g.
+// 32-bit ifInOctets/ifOutOctets), a decrease is taken as a wrap at 2^bits.
+// For 64-bit counters (ifHCInOctets-style) a decrease is taken as a counter
+// reset and cur is returned; no 64-bit wrap is computed.
+func wrapDelta(prev, cur uint64, bits int) uint64 {
+	if cur >= prev {
+		return cur - prev
+	}
+	if bits >= 64 {
+		// Treated as a counter reset: the delta is the count since the
+		// reset, whatever prev was.
+		return cur
+	}
+	wrap := uint64(1) << uint(bits)
+	return wrap - prev + cur
+}
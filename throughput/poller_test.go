@@ -0,0 +1,26 @@
+package throughput
+
+import "testing"
+
+func TestWrapDelta(t *testing.T) {
+	cases := []struct {
+		name      string
+		prev, cur uint64
+		bits      int
+		wantDelta uint64
+	}{
+		{"no wrap", 100, 150, 32, 50},
+		{"unchanged", 100, 100, 32, 0},
+		{"32-bit wraparound", 4294967290, 5, 32, 11},
+		{"64-bit counter reset", 18446744073709551610, 5, 64, 5},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := wrapDelta(c.prev, c.cur, c.bits)
+			if got != c.wantDelta {
+				t.Errorf("wrapDelta(%d, %d, %d) = %d, want %d", c.prev, c.cur, c.bits, got, c.wantDelta)
+			}
+		})
+	}
+}
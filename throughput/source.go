@@ -0,0 +1,17 @@
+// Package throughput polls a router/gateway's uplink interface counters and
+// turns successive reads into a bits/sec time series, so it can be
+// correlated against probe latency.
+package throughput
+
+import "context"
+
+// Source reads the current cumulative inbound/outbound octet counters for a
+// monitored interface.
+type Source interface {
+	// ReadCounters returns the interface's current cumulative octet
+	// counters.
+	ReadCounters(ctx context.Context) (inOctets, outOctets uint64, err error)
+	// CounterBits reports the counter width (32 or 64) so wraparound can be
+	// handled correctly when differencing successive reads.
+	CounterBits() int
+}